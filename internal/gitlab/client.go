@@ -0,0 +1,277 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	xgitlab "github.com/xanzy/go-gitlab"
+
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/scheduler"
+)
+
+// ErrNotFound is returned (wrapped) by DeleteArtifacts and similar calls
+// when GitLab responds 404, e.g. a job with no artifacts or a job ID that
+// doesn't exist. Callers should check it with errors.Is rather than
+// inspecting a response status code, since a retried call only ever
+// surfaces its final, terminal error.
+var ErrNotFound = xgitlab.ErrNotFound
+
+// AuthMethod selects how Token is presented to GitLab.
+type AuthMethod string
+
+const (
+	// AuthMethodToken sends Token as a personal/project access token
+	// ("PRIVATE-TOKEN" header).
+	AuthMethodToken AuthMethod = "token"
+	// AuthMethodOAuth sends Token as an OAuth2 bearer token.
+	AuthMethodOAuth AuthMethod = "oauth"
+	// AuthMethodJobToken sends Token as a CI job token ("JOB-TOKEN"
+	// header), the credential GitLab CI exposes as $CI_JOB_TOKEN.
+	AuthMethodJobToken AuthMethod = "job-token"
+)
+
+// ClientConfig configures NewClient. Server may be a bare host
+// ("gitlab.example.com"), include a scheme, and include a path prefix for
+// GitLab instances mounted under a subpath (e.g.
+// "https://example.com/gitlab/").
+type ClientConfig struct {
+	Server             string
+	Token              string
+	AuthMethod         AuthMethod
+	CACertPath         string
+	InsecureSkipVerify bool
+}
+
+// Client is a thin wrapper around the xanzy/go-gitlab client that speaks in
+// this package's Job type and integrates with internal/scheduler for
+// concurrency bounding, rate limiting and retries.
+type Client struct {
+	raw *xgitlab.Client
+}
+
+// NewClient builds a Client from cfg, resolving Server to a full base URL
+// (defaulting to https:// and preserving any path prefix) and configuring
+// TLS and authentication as requested.
+func NewClient(cfg ClientConfig) (*Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuring TLS: %w", err)
+	}
+
+	baseURL := normalizeBaseURL(cfg.Server)
+	opts := []xgitlab.ClientOptionFunc{
+		xgitlab.WithBaseURL(baseURL),
+		xgitlab.WithHTTPClient(httpClient),
+		// internal/scheduler already retries with backoff and jitter and
+		// honors Retry-After/RateLimit-Reset; disable the client's own
+		// retries so requests aren't retried twice over.
+		xgitlab.WithCustomRetryMax(0),
+	}
+
+	var raw *xgitlab.Client
+	switch cfg.AuthMethod {
+	case "", AuthMethodToken:
+		raw, err = xgitlab.NewClient(cfg.Token, opts...)
+	case AuthMethodOAuth:
+		raw, err = xgitlab.NewOAuthClient(cfg.Token, opts...)
+	case AuthMethodJobToken:
+		raw, err = xgitlab.NewJobClient(cfg.Token, opts...)
+	default:
+		return nil, fmt.Errorf("unknown auth method %q", cfg.AuthMethod)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating GitLab client: %w", err)
+	}
+
+	return &Client{raw: raw}, nil
+}
+
+// normalizeBaseURL adds a https:// scheme when server has none, so callers
+// can keep passing a bare host like the tool has always accepted, while
+// still allowing a full URL (with path prefix or http:// for self-signed
+// local setups) to pass through untouched.
+func normalizeBaseURL(server string) string {
+	if strings.Contains(server, "://") {
+		return server
+	}
+	return "https://" + server
+}
+
+func newHTTPClient(cfg ClientConfig) (*http.Client, error) {
+	if cfg.CACertPath == "" && !cfg.InsecureSkipVerify {
+		return http.DefaultClient, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CACertPath != "" {
+		pem, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA cert %s: %w", cfg.CACertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}, nil
+}
+
+// ProjectExists checks whether project (a numeric ID or a "group/path"
+// string) exists and is reachable with the configured credentials.
+// onResponse, if set, is called with the response status code for metrics
+// purposes.
+func (c *Client) ProjectExists(ctx context.Context, project interface{}, onResponse func(statusCode int)) (bool, error) {
+	_, resp, err := c.raw.Projects.GetProject(project, nil, xgitlab.WithContext(ctx))
+	if resp != nil && onResponse != nil {
+		onResponse(resp.StatusCode)
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListProjectJobs paginates GET /projects/:id/jobs, collecting every job
+// across all scopes requested in opts.
+func (c *Client) ListProjectJobs(ctx context.Context, project interface{}, opts ListJobsOptions) ([]Job, error) {
+	return c.listJobs(ctx, opts, func(listOpts *xgitlab.ListJobsOptions, reqOpts ...xgitlab.RequestOptionFunc) ([]*xgitlab.Job, *xgitlab.Response, error) {
+		return c.raw.Jobs.ListProjectJobs(project, listOpts, reqOpts...)
+	})
+}
+
+// ListPipelineJobs paginates GET /projects/:id/pipelines/:pipeline_id/jobs.
+func (c *Client) ListPipelineJobs(ctx context.Context, project interface{}, pipelineID int, opts ListJobsOptions) ([]Job, error) {
+	return c.listJobs(ctx, opts, func(listOpts *xgitlab.ListJobsOptions, reqOpts ...xgitlab.RequestOptionFunc) ([]*xgitlab.Job, *xgitlab.Response, error) {
+		return c.raw.Jobs.ListPipelineJobs(project, pipelineID, listOpts, reqOpts...)
+	})
+}
+
+func (c *Client) listJobs(ctx context.Context, opts ListJobsOptions, list func(*xgitlab.ListJobsOptions, ...xgitlab.RequestOptionFunc) ([]*xgitlab.Job, *xgitlab.Response, error)) ([]Job, error) {
+	var scopes *[]xgitlab.BuildStateValue
+	if len(opts.Scopes) > 0 {
+		s := make([]xgitlab.BuildStateValue, len(opts.Scopes))
+		for i, scope := range opts.Scopes {
+			s[i] = xgitlab.BuildStateValue(scope)
+		}
+		scopes = &s
+	}
+
+	var all []Job
+	for page := 1; ; page++ {
+		listOpts := &xgitlab.ListJobsOptions{
+			ListOptions: xgitlab.ListOptions{PerPage: opts.perPage(), Page: page},
+			Scope:       scopes,
+		}
+
+		var (
+			jobs []*xgitlab.Job
+			resp *xgitlab.Response
+			err  error
+		)
+		do := func() error {
+			jobs, resp, err = list(listOpts, xgitlab.WithContext(ctx))
+			return err
+		}
+		if opts.Scheduler != nil {
+			_, err = opts.Scheduler.Submit(func() (*http.Response, error) {
+				callErr := do()
+				if resp != nil {
+					return resp.Response, callErr
+				}
+				return nil, callErr
+			})
+		} else {
+			err = do()
+		}
+		if resp != nil {
+			opts.observe(resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing jobs page %d: %w", page, err)
+		}
+
+		for _, job := range jobs {
+			all = append(all, fromAPIJob(job))
+		}
+		if len(jobs) < opts.perPage() {
+			break
+		}
+	}
+
+	return all, nil
+}
+
+// DeleteArtifacts deletes the artifacts archive for a single job.
+func (c *Client) DeleteArtifacts(ctx context.Context, sched *scheduler.Scheduler, project interface{}, jobID int) (*http.Response, error) {
+	do := func() (*http.Response, error) {
+		resp, err := c.raw.Jobs.DeleteArtifacts(project, jobID, xgitlab.WithContext(ctx))
+		if resp != nil {
+			return resp.Response, err
+		}
+		return nil, err
+	}
+	if sched != nil {
+		return sched.Submit(do)
+	}
+	return do()
+}
+
+// DeleteProjectArtifacts deletes every artifact in project that GitLab
+// considers eligible for deletion (expired, or already past any retention
+// policy) in a single call, instead of iterating jobs one by one.
+func (c *Client) DeleteProjectArtifacts(ctx context.Context, sched *scheduler.Scheduler, project interface{}) (*http.Response, error) {
+	do := func() (*http.Response, error) {
+		resp, err := c.raw.Jobs.DeleteProjectArtifacts(project, xgitlab.WithContext(ctx))
+		if resp != nil {
+			return resp.Response, err
+		}
+		return nil, err
+	}
+	if sched != nil {
+		return sched.Submit(do)
+	}
+	return do()
+}
+
+func fromAPIJob(job *xgitlab.Job) Job {
+	j := Job{
+		ID:         job.ID,
+		Status:     job.Status,
+		Ref:        job.Ref,
+		Name:       job.Name,
+		Tag:        job.Tag,
+		FinishedAt: job.FinishedAt,
+		Pipeline: Pipeline{
+			ID:  job.Pipeline.ID,
+			Ref: job.Pipeline.Ref,
+		},
+	}
+	if job.CreatedAt != nil {
+		j.CreatedAt = *job.CreatedAt
+	}
+	if job.ArtifactsFile.Filename != "" {
+		j.ArtifactsFile = &ArtifactsFile{
+			Filename: job.ArtifactsFile.Filename,
+			Size:     int64(job.ArtifactsFile.Size),
+		}
+	}
+	for _, a := range job.Artifacts {
+		j.Artifacts = append(j.Artifacts, Artifact{
+			FileType: a.FileType,
+			Size:     int64(a.Size),
+			Filename: a.Filename,
+		})
+	}
+	j.ArtifactsExpireAt = job.ArtifactsExpireAt
+	return j
+}
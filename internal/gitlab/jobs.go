@@ -0,0 +1,87 @@
+// Package gitlab wraps the pieces of the GitLab REST API that
+// artifact-cleaner needs — checking a project exists, listing jobs
+// (project-wide or scoped to a single pipeline), and deleting artifacts —
+// behind a typed client backed by github.com/xanzy/go-gitlab, instead of
+// hand-rolled HTTP requests.
+package gitlab
+
+import (
+	"time"
+
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/scheduler"
+)
+
+const defaultPerPage = 100
+
+// ArtifactsFile describes the archive attached to a job, as returned under
+// the job's "artifacts_file" key.
+type ArtifactsFile struct {
+	Filename string
+	Size     int64
+}
+
+// Artifact describes a single entry in a job's "artifacts" array.
+type Artifact struct {
+	FileType string
+	Size     int64
+	Filename string
+}
+
+// Pipeline is the subset of pipeline fields embedded in a Job.
+type Pipeline struct {
+	ID  int
+	Ref string
+}
+
+// Job is the subset of the GitLab job resource that artifact-cleaner cares
+// about. See https://docs.gitlab.com/ee/api/jobs.html.
+type Job struct {
+	ID                int
+	Status            string
+	Ref               string
+	Name              string
+	Tag               bool
+	CreatedAt         time.Time
+	FinishedAt        *time.Time
+	ArtifactsFile     *ArtifactsFile
+	Artifacts         []Artifact
+	ArtifactsExpireAt *time.Time
+	Pipeline          Pipeline
+}
+
+// HasArtifacts reports whether the job has anything for the cleaner to
+// delete: an artifacts archive, individual artifact entries, or an
+// expiration set on artifacts that haven't been swept yet.
+func (j Job) HasArtifacts() bool {
+	return j.ArtifactsFile != nil || len(j.Artifacts) > 0 || j.ArtifactsExpireAt != nil
+}
+
+// ListJobsOptions controls pagination and filtering for ListProjectJobs and
+// ListPipelineJobs.
+type ListJobsOptions struct {
+	// Scopes maps to the repeated "scope[]" query parameter, e.g.
+	// []string{"success", "failed"}.
+	Scopes []string
+	// PerPage overrides the default page size (100, GitLab's max).
+	PerPage int
+	// Scheduler, if set, routes each page request through it so listing
+	// shares the same rate limit and retry/backoff behavior as artifact
+	// deletion. A nil Scheduler issues requests directly.
+	Scheduler *scheduler.Scheduler
+	// OnResponse, if set, is called with the HTTP status code of every
+	// page request, successful or not, for metrics purposes.
+	OnResponse func(statusCode int)
+}
+
+func (o ListJobsOptions) observe(statusCode int) {
+	if o.OnResponse != nil {
+		o.OnResponse(statusCode)
+	}
+}
+
+func (o ListJobsOptions) perPage() int {
+	if o.PerPage > 0 {
+		return o.PerPage
+	}
+	return defaultPerPage
+}
@@ -0,0 +1,83 @@
+// Package metrics exposes the Prometheus metrics artifact-cleaner emits
+// when run with --metrics-addr, so it can be deployed as a long-lived
+// cron/Job in Kubernetes and observed like any other service.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds every collector artifact-cleaner reports.
+type Metrics struct {
+	ArtifactsDeletedTotal  *prometheus.CounterVec
+	ArtifactDeleteDuration *prometheus.HistogramVec
+	GitLabAPIRequestsTotal *prometheus.CounterVec
+	InflightDeletions      prometheus.Gauge
+	ReclaimedBytes         prometheus.Gauge
+	registry               *prometheus.Registry
+}
+
+// New registers all collectors against a fresh registry.
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	factory := promauto.With(registry)
+
+	return &Metrics{
+		registry: registry,
+		ArtifactsDeletedTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "artifacts_deleted_total",
+			Help: "Number of job artifact deletions attempted, by outcome.",
+		}, []string{"status"}),
+		ArtifactDeleteDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "artifact_delete_duration_seconds",
+			Help:    "Latency of individual artifact delete requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"status"}),
+		GitLabAPIRequestsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "gitlab_api_requests_total",
+			Help: "Requests made to the GitLab API, by response code.",
+		}, []string{"code"}),
+		InflightDeletions: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "inflight_deletions",
+			Help: "Number of artifact delete requests currently in flight.",
+		}),
+		ReclaimedBytes: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "reclaimed_bytes",
+			Help: "Cumulative bytes of artifacts reclaimed by successful deletions.",
+		}),
+	}
+}
+
+// Serve starts an HTTP server exposing /metrics on addr and blocks until it
+// stops. Callers typically run it in its own goroutine.
+func (m *Metrics) Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ObserveDelete records the outcome of a single delete attempt: its status
+// label (deleted, skipped, failed), how long it took, and how many bytes
+// were reclaimed (0 unless status is "deleted").
+func (m *Metrics) ObserveDelete(ctx context.Context, status string, durationSeconds float64, reclaimedBytes int64) {
+	m.ArtifactsDeletedTotal.WithLabelValues(status).Inc()
+	m.ArtifactDeleteDuration.WithLabelValues(status).Observe(durationSeconds)
+	if reclaimedBytes > 0 {
+		m.ReclaimedBytes.Add(float64(reclaimedBytes))
+	}
+}
+
+// ObserveAPIRequest records a single GitLab API call's response code.
+func (m *Metrics) ObserveAPIRequest(code string) {
+	m.GitLabAPIRequestsTotal.WithLabelValues(code).Inc()
+}
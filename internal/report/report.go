@@ -0,0 +1,113 @@
+// Package report renders the outcome of an artifact-cleaner run (or
+// --dry-run preview) as JSON, newline-delimited JSON, or a plain text
+// summary, so operators can audit or feed large cleanups into downstream
+// tooling before or after the fact.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Action describes what happened (or would happen, under --dry-run) to a
+// job's artifacts.
+type Action string
+
+const (
+	ActionWouldDelete Action = "would-delete"
+	ActionDeleted     Action = "deleted"
+	ActionSkipped     Action = "skipped"
+	ActionFailed      Action = "failed"
+)
+
+// Format selects how records are rendered.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatText   Format = "text"
+)
+
+// ParseFormat validates a --report-format flag value.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatJSON, FormatNDJSON, FormatText:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unknown report format %q (want json, ndjson or text)", s)
+	}
+}
+
+// Record is one line of the report: a single job and the action taken (or
+// that would be taken) on its artifacts.
+type Record struct {
+	JobID        int        `json:"job_id"`
+	PipelineID   int        `json:"pipeline_id,omitempty"`
+	Ref          string     `json:"ref,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	ArtifactSize int64      `json:"artifact_size,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Action       Action     `json:"action"`
+}
+
+// Writer accumulates records and renders them in the configured format.
+// It is safe for concurrent use by multiple goroutines calling Add.
+type Writer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	format  Format
+	records []Record
+}
+
+// NewWriter returns a Writer that renders to out in the given format.
+func NewWriter(out io.Writer, format Format) *Writer {
+	return &Writer{out: out, format: format}
+}
+
+// Add records one job's outcome. Under FormatNDJSON and FormatText, it is
+// written to out immediately; under FormatJSON, it is buffered until Close
+// so the whole report can be emitted as a single JSON array.
+func (w *Writer) Add(r Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch w.format {
+	case FormatJSON:
+		w.records = append(w.records, r)
+		return nil
+	case FormatNDJSON:
+		enc := json.NewEncoder(w.out)
+		return enc.Encode(r)
+	default: // FormatText
+		_, err := fmt.Fprintln(w.out, formatText(r))
+		return err
+	}
+}
+
+// Close flushes any buffered records. It is a no-op for formats that write
+// eagerly.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.format != FormatJSON {
+		return nil
+	}
+
+	enc := json.NewEncoder(w.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(w.records)
+}
+
+func formatText(r Record) string {
+	finished := "-"
+	if r.FinishedAt != nil {
+		finished = r.FinishedAt.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("job=%d pipeline=%d ref=%s status=%s size=%d finished_at=%s action=%s",
+		r.JobID, r.PipelineID, r.Ref, r.Status, r.ArtifactSize, finished, r.Action)
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{"json", FormatJSON, false},
+		{"ndjson", FormatNDJSON, false},
+		{"text", FormatText, false},
+		{"yaml", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriterNDJSONWritesImmediately(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatNDJSON)
+
+	if err := w.Add(Record{JobID: 1, Action: ActionDeleted}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"job_id":1`) {
+		t.Errorf("expected record written immediately, got %q", buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestWriterJSONBuffersUntilClose(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatJSON)
+
+	if err := w.Add(Record{JobID: 1, Action: ActionWouldDelete}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output before Close(), got %q", buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"job_id": 1`) {
+		t.Errorf("expected record in output after Close(), got %q", buf.String())
+	}
+}
+
+func TestWriterTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatText)
+
+	if err := w.Add(Record{JobID: 42, Ref: "main", Action: ActionSkipped}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "job=42") || !strings.Contains(buf.String(), "action=skipped") {
+		t.Errorf("unexpected text output: %q", buf.String())
+	}
+}
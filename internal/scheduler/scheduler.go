@@ -0,0 +1,189 @@
+// Package scheduler provides a bounded worker pool that every outgoing
+// GitLab API call funnels through, so the tool never exceeds a configured
+// request rate and backs off sanely when GitLab pushes back.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	maxAttempts  = 5
+	baseBackoff  = 500 * time.Millisecond
+	maxBackoff   = 30 * time.Second
+	maxRateSleep = 5 * time.Minute
+)
+
+// job is a unit of work submitted to the scheduler: an HTTP call to make,
+// and where to deliver its outcome.
+type job struct {
+	do     func() (*http.Response, error)
+	result chan<- jobResult
+}
+
+type jobResult struct {
+	resp *http.Response
+	err  error
+}
+
+// Scheduler runs submitted HTTP calls across a fixed pool of workers,
+// throttled by a shared rate limiter and retried with exponential backoff
+// and jitter on 5xx responses or transport errors. It honors Retry-After
+// and RateLimit-Reset response headers by parking the worker that hit them
+// until the indicated time.
+type Scheduler struct {
+	jobs    chan job
+	limiter *rate.Limiter
+	wg      sync.WaitGroup
+}
+
+// NewLimiter builds a rate.Limiter for rps requests/second and the given
+// burst. An rps of zero or less disables throttling (returns nil, which
+// Scheduler treats as unlimited).
+func NewLimiter(rps float64, burst int) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// New starts a Scheduler with the given number of workers, each call
+// throttled to limiter's rate and burst. A nil limiter disables throttling.
+func New(workers int, limiter *rate.Limiter) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &Scheduler{
+		jobs:    make(chan job, workers),
+		limiter: limiter,
+	}
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+// Close stops accepting new work and waits for in-flight jobs to drain.
+func (s *Scheduler) Close() {
+	close(s.jobs)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) worker() {
+	defer s.wg.Done()
+	for j := range s.jobs {
+		resp, err := s.run(j.do)
+		j.result <- jobResult{resp: resp, err: err}
+		close(j.result)
+	}
+}
+
+// Submit enqueues an HTTP call and blocks until a worker has executed it
+// (with retries) and returned a final result.
+func (s *Scheduler) Submit(do func() (*http.Response, error)) (*http.Response, error) {
+	result := make(chan jobResult, 1)
+	s.jobs <- job{do: do, result: result}
+	r := <-result
+	return r.resp, r.err
+}
+
+func (s *Scheduler) run(do func() (*http.Response, error)) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if s.limiter != nil {
+			if err := s.limiter.Wait(context.Background()); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := do()
+
+		// A nil response means the call never reached GitLab (transport
+		// error, context cancellation, etc.); that's always retryable.
+		// A non-nil response is retryable only for 429/5xx, regardless of
+		// whether the caller's client wrapped it in a non-nil error, too
+		// (e.g. go-gitlab returns a non-nil error on every non-2xx/304
+		// response). Anything else, including a 404, is a terminal result:
+		// return it immediately instead of burning through maxAttempts.
+		if resp == nil {
+			lastErr = err
+			time.Sleep(backoffWithJitter(attempt))
+			continue
+		}
+
+		if wait, ok := retryAfter(resp); ok {
+			resp.Body.Close()
+			sleepCapped(wait)
+			lastErr = fmt.Errorf("rate limited (status %d)", resp.StatusCode)
+			continue
+		}
+
+		if resp.StatusCode >= 500 {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("server error: status %d", resp.StatusCode)
+			time.Sleep(backoffWithJitter(attempt))
+			continue
+		}
+
+		return resp, err
+	}
+	return nil, lastErr
+}
+
+// retryAfter inspects Retry-After and RateLimit-Reset response headers,
+// returning how long the worker should wait before retrying.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	if v := resp.Header.Get("RateLimit-Reset"); v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			wait := time.Until(time.Unix(epoch, 0))
+			if wait < 0 {
+				wait = 0
+			}
+			return wait, true
+		}
+	}
+
+	// 429 without a usable header: fall back to a short fixed wait.
+	return time.Second, true
+}
+
+func sleepCapped(d time.Duration) {
+	if d > maxRateSleep {
+		d = maxRateSleep
+	}
+	time.Sleep(d)
+}
+
+// backoffWithJitter returns an exponential backoff duration for the given
+// (zero-indexed) attempt, with up to 50% jitter to avoid thundering herds.
+func backoffWithJitter(attempt int) time.Duration {
+	backoff := time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempt)))
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff/2 + jitter
+}
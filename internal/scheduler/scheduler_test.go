@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		headers    map[string]string
+		wantOK     bool
+		wantWait   time.Duration
+	}{
+		{
+			name:       "not rate limited",
+			statusCode: http.StatusOK,
+			wantOK:     false,
+		},
+		{
+			name:       "Retry-After in seconds",
+			statusCode: http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "5"},
+			wantOK:     true,
+			wantWait:   5 * time.Second,
+		},
+		{
+			name:       "no usable header falls back to a short wait",
+			statusCode: http.StatusTooManyRequests,
+			wantOK:     true,
+			wantWait:   time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.statusCode, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			wait, ok := retryAfter(resp)
+			if ok != tt.wantOK {
+				t.Fatalf("retryAfter() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && wait != tt.wantWait {
+				t.Errorf("retryAfter() wait = %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestSubmitReturnsTerminal404WithoutRetrying(t *testing.T) {
+	var attempts int32
+	errNotFound := errors.New("404 Not Found")
+
+	s := New(1, nil)
+	defer s.Close()
+
+	resp, err := s.Submit(func() (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, errNotFound
+	})
+
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("attempts = %d, want 1 (404 must not be retried)", got)
+	}
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("err = %v, want %v", err, errNotFound)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("resp = %+v, want StatusCode %d", resp, http.StatusNotFound)
+	}
+}
+
+func TestSubmitRetriesServerErrorsUntilSuccess(t *testing.T) {
+	var attempts int32
+
+	s := New(1, nil)
+	defer s.Close()
+
+	resp, err := s.Submit(func() (*http.Response, error) {
+		n := atomic.AddInt32(&attempts, 1)
+		if n < 3 {
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}, errors.New("boom")
+		}
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+	if err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("resp = %+v, want StatusCode %d", resp, http.StatusNoContent)
+	}
+}
+
+func TestBackoffWithJitterStaysWithinBounds(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		backoff := backoffWithJitter(attempt)
+		if backoff <= 0 {
+			t.Fatalf("backoffWithJitter(%d) = %v, want positive", attempt, backoff)
+		}
+		if backoff > maxBackoff {
+			t.Errorf("backoffWithJitter(%d) = %v, want <= maxBackoff (%v)", attempt, backoff, maxBackoff)
+		}
+	}
+}
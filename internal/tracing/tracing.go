@@ -0,0 +1,58 @@
+// Package tracing wires up OpenTelemetry tracing around the tool's
+// GitLab calls, guarded by --otel-endpoint. When no endpoint is
+// configured, Start returns a no-op tracer so call sites don't need to
+// branch on whether tracing is enabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/dxas90/gitlab-artifacts-cleaner"
+
+// Shutdown flushes and stops the tracer provider started by Start. It is a
+// no-op when tracing was never enabled.
+type Shutdown func(context.Context) error
+
+// Start configures the global OpenTelemetry tracer provider to export spans
+// to endpoint via OTLP/HTTP. If endpoint is empty, tracing is left
+// disabled and Tracer() returns a no-op tracer.
+func Start(ctx context.Context, endpoint string) (Shutdown, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("artifact-cleaner"),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("building OTel resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer artifact-cleaner's call sites should use.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
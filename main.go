@@ -1,15 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
+
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/gitlab"
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/metrics"
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/report"
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/scheduler"
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/tracing"
+)
+
+const (
+	minConcurrency        = 1
+	maxConcurrency        = 1000
+	maxJobRange           = 1000000
+	minProjectConcurrency = 1
+	maxProjectConcurrency = 1000
 )
 
 // getEnv returns the value of an environment variable or a default if it's not set
@@ -20,66 +39,128 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// projectExists checks if a GitLab project exists
-func projectExists(server, token string, projectID int) (bool, error) {
-	url := fmt.Sprintf("https://%s/api/v4/projects/%d", server, projectID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return false, err
+// validateInputs checks the flag/env values the tool was invoked with before
+// any network calls are made.
+func validateInputs(server, token string, projectID, startJob, endJob, concurrency, projectConcurrency int) error {
+	if server == "" {
+		return fmt.Errorf("gitlab server must not be empty")
 	}
-	req.Header.Set("PRIVATE-TOKEN", token)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
+	if token == "" {
+		return fmt.Errorf("gitlab token must not be empty")
 	}
-	defer resp.Body.Close()
+	if projectID <= 0 {
+		return fmt.Errorf("project ID must be positive, got %d", projectID)
+	}
+	if startJob <= 0 {
+		return fmt.Errorf("start job must be positive, got %d", startJob)
+	}
+	if endJob < startJob {
+		return fmt.Errorf("end job (%d) must not be less than start job (%d)", endJob, startJob)
+	}
+	if concurrency < minConcurrency || concurrency > maxConcurrency {
+		return fmt.Errorf("concurrency must be between %d and %d, got %d", minConcurrency, maxConcurrency, concurrency)
+	}
+	if projectConcurrency < minProjectConcurrency || projectConcurrency > maxProjectConcurrency {
+		return fmt.Errorf("project concurrency must be between %d and %d, got %d", minProjectConcurrency, maxProjectConcurrency, projectConcurrency)
+	}
+	if endJob-startJob+1 > maxJobRange {
+		return fmt.Errorf("job range too large: %d jobs requested, max is %d", endJob-startJob+1, maxJobRange)
+	}
+	return nil
+}
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return true, nil
-	case http.StatusNotFound:
-		return false, nil
-	default:
-		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// projectExists checks if a GitLab project exists.
+func projectExists(ctx context.Context, m *metrics.Metrics, client *gitlab.Client, project interface{}) (bool, error) {
+	_, span := tracing.Tracer().Start(ctx, "projectExists")
+	defer span.End()
+
+	return client.ProjectExists(ctx, project, func(statusCode int) {
+		m.ObserveAPIRequest(strconv.Itoa(statusCode))
+	})
+}
+
+// jobRecord builds the report record for job, defaulting FinishedAt/size
+// fields that only discovery mode (not the plain ID range) can populate.
+func jobRecord(job gitlab.Job, action report.Action) report.Record {
+	var size int64
+	if job.ArtifactsFile != nil {
+		size = job.ArtifactsFile.Size
+	}
+	return report.Record{
+		JobID:        job.ID,
+		PipelineID:   job.Pipeline.ID,
+		Ref:          job.Ref,
+		Status:       job.Status,
+		ArtifactSize: size,
+		FinishedAt:   job.FinishedAt,
+		Action:       action,
 	}
 }
 
-// deleteArtifact deletes a job artifact with retries and logs
-func deleteArtifact(server, token string, projectID, jobID int, wg *sync.WaitGroup, sem chan struct{}, successCounter, failureCounter *int, mu *sync.Mutex, logger *log.Logger) {
-	defer wg.Done()
+// deleteArtifact deletes a job artifact, logs the outcome, updates the
+// shared counters and records the action taken. Under dryRun it only
+// records what would happen; no DELETE request is issued. The request
+// itself, including retries, backoff and rate limiting, is handled by
+// sched. Callers are responsible for their own fan-out and WaitGroup
+// bookkeeping; deleteArtifact itself runs synchronously.
+func deleteArtifact(ctx context.Context, client *gitlab.Client, sched *scheduler.Scheduler, m *metrics.Metrics, rpt *report.Writer, dryRun bool, project interface{}, job gitlab.Job, successCounter, failureCounter *int, mu *sync.Mutex, logger *log.Logger) {
+	ctx, span := tracing.Tracer().Start(ctx, "deleteArtifact")
+	defer span.End()
 
-	sem <- struct{}{}        // acquire semaphore
-	defer func() { <-sem }() // release semaphore
+	if dryRun {
+		msg := fmt.Sprintf("Job %d: would delete artifact (dry-run)", job.ID)
+		fmt.Println(msg)
+		logger.Println(msg)
+		if err := rpt.Add(jobRecord(job, report.ActionWouldDelete)); err != nil {
+			logger.Printf("Job %d: failed to write report record: %v\n", job.ID, err)
+		}
+		return
+	}
 
-	url := fmt.Sprintf("https://%s/api/v4/projects/%d/jobs/%d/artifacts", server, projectID, jobID)
-	req, _ := http.NewRequest("DELETE", url, nil)
-	req.Header.Set("PRIVATE-TOKEN", token)
+	m.InflightDeletions.Inc()
+	defer m.InflightDeletions.Dec()
+	start := time.Now()
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	var resp *http.Response
-	var err error
+	jobID := job.ID
+	resp, err := client.DeleteArtifacts(ctx, sched, project, jobID)
 
-	for i := 0; i < 3; i++ { // retry 3 times
-		resp, err = client.Do(req)
-		if err == nil {
-			break
+	if errors.Is(err, gitlab.ErrNotFound) {
+		msg := fmt.Sprintf("Job %d: no artifacts found", jobID)
+		fmt.Println(msg)
+		logger.Println(msg)
+		if resp != nil {
+			m.ObserveAPIRequest(strconv.Itoa(resp.StatusCode))
+			resp.Body.Close()
 		}
-		time.Sleep(2 * time.Second)
+		m.ObserveDelete(ctx, string(report.ActionSkipped), time.Since(start).Seconds(), 0)
+		if rptErr := rpt.Add(jobRecord(job, report.ActionSkipped)); rptErr != nil {
+			logger.Printf("Job %d: failed to write report record: %v\n", jobID, rptErr)
+		}
+		return
 	}
 
 	if err != nil {
 		msg := fmt.Sprintf("Job %d: request failed after retries: %v", jobID, err)
 		fmt.Println(msg)
 		logger.Println(msg)
+		if resp != nil {
+			m.ObserveAPIRequest(strconv.Itoa(resp.StatusCode))
+			resp.Body.Close()
+		}
 		mu.Lock()
 		*failureCounter++
 		mu.Unlock()
+		m.ObserveDelete(ctx, "failed", time.Since(start).Seconds(), 0)
+		if rptErr := rpt.Add(jobRecord(job, report.ActionFailed)); rptErr != nil {
+			logger.Printf("Job %d: failed to write report record: %v\n", jobID, rptErr)
+		}
 		return
 	}
 	defer resp.Body.Close()
+	m.ObserveAPIRequest(strconv.Itoa(resp.StatusCode))
 
+	var action report.Action
+	var reclaimedBytes int64
 	switch resp.StatusCode {
 	case http.StatusNoContent:
 		msg := fmt.Sprintf("Job %d: artifact deleted successfully", jobID)
@@ -88,10 +169,10 @@ func deleteArtifact(server, token string, projectID, jobID int, wg *sync.WaitGro
 		mu.Lock()
 		*successCounter++
 		mu.Unlock()
-	case http.StatusNotFound:
-		msg := fmt.Sprintf("Job %d: no artifacts found", jobID)
-		fmt.Println(msg)
-		logger.Println(msg)
+		action = report.ActionDeleted
+		if job.ArtifactsFile != nil {
+			reclaimedBytes = job.ArtifactsFile.Size
+		}
 	default:
 		msg := fmt.Sprintf("Job %d: failed to delete artifact (status: %s)", jobID, resp.Status)
 		fmt.Println(msg)
@@ -99,18 +180,314 @@ func deleteArtifact(server, token string, projectID, jobID int, wg *sync.WaitGro
 		mu.Lock()
 		*failureCounter++
 		mu.Unlock()
+		action = report.ActionFailed
+	}
+
+	m.ObserveDelete(ctx, string(action), time.Since(start).Seconds(), reclaimedBytes)
+
+	if err := rpt.Add(jobRecord(job, action)); err != nil {
+		logger.Printf("Job %d: failed to write report record: %v\n", jobID, err)
+	}
+}
+
+// burstOrDefault returns burst if positive, otherwise falls back to
+// concurrency so a configured --rps without an explicit --burst still lets
+// every worker make one request before throttling kicks in.
+func burstOrDefault(burst, concurrency int) int {
+	if burst > 0 {
+		return burst
+	}
+	return concurrency
+}
+
+// discoverJobs lists jobs via the Jobs API (scoped to a pipeline if
+// pipelineID is set) and returns those matching the ref, olderThan and
+// onlyWithArtifacts filters. An olderThan of zero disables the age filter.
+func discoverJobs(ctx context.Context, client *gitlab.Client, sched *scheduler.Scheduler, m *metrics.Metrics, project interface{}, pipelineID int, scopes []string, ref string, olderThan time.Duration, onlyWithArtifacts bool) ([]gitlab.Job, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "job discovery")
+	defer span.End()
+
+	opts := gitlab.ListJobsOptions{Scopes: scopes, Scheduler: sched, OnResponse: func(statusCode int) {
+		m.ObserveAPIRequest(strconv.Itoa(statusCode))
+	}}
+
+	var jobs []gitlab.Job
+	var err error
+	if pipelineID > 0 {
+		jobs, err = client.ListPipelineJobs(ctx, project, pipelineID, opts)
+	} else {
+		jobs, err = client.ListProjectJobs(ctx, project, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var matched []gitlab.Job
+	for _, job := range jobs {
+		if ref != "" && job.Ref != ref {
+			continue
+		}
+		if onlyWithArtifacts && !job.HasArtifacts() {
+			continue
+		}
+		if olderThan > 0 {
+			if job.FinishedAt == nil || job.FinishedAt.After(cutoff) {
+				continue
+			}
+		}
+		matched = append(matched, job)
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+// openReportWriter resolves --report-file to an output destination (stdout
+// if empty) and wraps it in a report.Writer for the given format. The
+// returned close func flushes buffered formats and closes any opened file;
+// it is always safe to call.
+func openReportWriter(path, format string) (*report.Writer, func() error, error) {
+	f, err := report.ParseFormat(format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := os.Stdout
+	if path != "" {
+		out, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening report file %s: %w", path, err)
+		}
+	}
+
+	rpt := report.NewWriter(out, f)
+	closeFn := func() error {
+		if err := rpt.Close(); err != nil {
+			return err
+		}
+		if out != os.Stdout {
+			return out.Close()
+		}
+		return nil
+	}
+	return rpt, closeFn, nil
+}
+
+// buildClient constructs the GitLab client used for every API call, resolving
+// server/auth/TLS flags into a gitlab.ClientConfig.
+func buildClient(server, token, authMethod, caCertPath string, insecureSkipVerify bool) (*gitlab.Client, error) {
+	return gitlab.NewClient(gitlab.ClientConfig{
+		Server:             server,
+		Token:              token,
+		AuthMethod:         gitlab.AuthMethod(authMethod),
+		CACertPath:         caCertPath,
+		InsecureSkipVerify: insecureSkipVerify,
+	})
+}
+
+// setupObservability starts OTel tracing (if otelEndpoint is set) and a
+// Prometheus metrics registry, serving /metrics on metricsAddr in the
+// background when it is set. The returned func shuts tracing down and
+// should be deferred by the caller.
+func setupObservability(ctx context.Context, metricsAddr, otelEndpoint string, logger *log.Logger) (*metrics.Metrics, func(), error) {
+	shutdownTracing, err := tracing.Start(ctx, otelEndpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("starting tracing: %w", err)
+	}
+
+	m := metrics.New()
+	if metricsAddr != "" {
+		go func() {
+			if err := m.Serve(metricsAddr); err != nil {
+				logger.Printf("metrics server stopped: %v\n", err)
+			}
+		}()
+	}
+
+	return m, func() {
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Printf("error shutting down tracing: %v\n", err)
+		}
+	}, nil
+}
+
+// projectResult summarizes the outcome of cleaning a single project, for
+// the per-project table printed once every project has been processed.
+type projectResult struct {
+	project   string
+	successes int
+	failures  int
+	err       error
+}
+
+// resolveProjects builds the list of project identifiers to clean: every
+// entry from projectsFlag (comma-separated) and projectsFile (one ID or
+// "group/path" per line), falling back to the single --project ID when
+// neither is set.
+func resolveProjects(projectID int, projectsFlag, projectsFile string) ([]string, error) {
+	var projects []string
+	for _, p := range strings.Split(projectsFlag, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			projects = append(projects, p)
+		}
+	}
+
+	if projectsFile != "" {
+		data, err := os.ReadFile(projectsFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading projects file %s: %w", projectsFile, err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				projects = append(projects, line)
+			}
+		}
+	}
+
+	if len(projects) == 0 {
+		projects = []string{strconv.Itoa(projectID)}
+	}
+	return projects, nil
+}
+
+// cleanProject validates that project exists and then either expires all of
+// its artifacts in one call (allExpired) or deletes them job by job, via
+// range or discovery, exactly like the original single-project behavior.
+func cleanProject(ctx context.Context, client *gitlab.Client, m *metrics.Metrics, rpt *report.Writer, logger *log.Logger, project string, opts cleanOptions) projectResult {
+	result := projectResult{project: project}
+
+	exists, err := projectExists(ctx, m, client, project)
+	if err != nil {
+		result.err = fmt.Errorf("checking project: %w", err)
+		return result
+	}
+	if !exists {
+		result.err = fmt.Errorf("project does not exist on %s", opts.server)
+		return result
+	}
+
+	sched := scheduler.New(opts.concurrency, opts.limiter)
+	defer sched.Close()
+
+	if opts.allExpired {
+		if opts.dryRun {
+			msg := fmt.Sprintf("Project %s: would expire all eligible artifacts (dry-run)", project)
+			fmt.Println(msg)
+			logger.Println(msg)
+			return result
+		}
+		if _, err := client.DeleteProjectArtifacts(ctx, sched, project); err != nil {
+			result.err = fmt.Errorf("expiring artifacts: %w", err)
+			return result
+		}
+		msg := fmt.Sprintf("Project %s: expired all eligible artifacts", project)
+		fmt.Println(msg)
+		logger.Println(msg)
+		result.successes = 1
+		return result
+	}
+
+	jobs := make(chan gitlab.Job, opts.concurrency)
+	go func() {
+		defer close(jobs)
+		if len(opts.scopes) > 0 || opts.pipelineID > 0 || opts.ref != "" || opts.olderThan > 0 || opts.onlyWithArtifacts {
+			discovered, err := discoverJobs(ctx, client, sched, m, project, opts.pipelineID, opts.scopes, opts.ref, opts.olderThan, opts.onlyWithArtifacts)
+			if err != nil {
+				logger.Printf("Project %s: error discovering jobs: %v\n", project, err)
+				fmt.Printf("Project %s: error discovering jobs: %v\n", project, err)
+				return
+			}
+			for _, job := range discovered {
+				jobs <- job
+			}
+			return
+		}
+		for jobID := opts.startJob; jobID <= opts.endJob; jobID++ {
+			jobs <- gitlab.Job{ID: jobID}
+		}
+	}()
+
+	// A fixed pool of opts.concurrency workers pulls from jobs, rather than
+	// one goroutine per job: a range of a million job IDs must not park a
+	// million goroutines, each blocked on sched.Submit.
+	workers := opts.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				deleteArtifact(ctx, client, sched, m, rpt, opts.dryRun, project, job, &result.successes, &result.failures, &mu, logger)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return result
+}
+
+// cleanOptions bundles the flags cleanProject needs per project, so adding
+// multi-project fan-out didn't require passing a dozen separate parameters.
+type cleanOptions struct {
+	server                        string
+	startJob, endJob, concurrency int
+	pipelineID                    int
+	scopes                        []string
+	ref                           string
+	olderThan                     time.Duration
+	onlyWithArtifacts             bool
+	allExpired                    bool
+	dryRun                        bool
+	rps                           float64
+	burst                         int
+	// limiter is shared across every project's scheduler so --rps bounds the
+	// aggregate request rate against the host, not a per-project rate.
+	limiter *rate.Limiter
+}
+
+// printProjectSummary renders the per-project outcome table printed after
+// every project has been processed.
+func printProjectSummary(results []projectResult) {
+	fmt.Println("\nProject       Successes  Failures  Error")
+	for _, r := range results {
+		errText := ""
+		if r.err != nil {
+			errText = r.err.Error()
+		}
+		fmt.Printf("%-13s %-10d %-9d %s\n", r.project, r.successes, r.failures, errText)
 	}
 }
 
 func main() {
 	var server, token string
-	var projectID, startJob, endJob, concurrency int
-	var logFile string
+	var projectID, startJob, endJob, concurrency, pipelineID, burst int
+	var logFile, ref string
+	var scopes []string
+	var olderThan time.Duration
+	var onlyWithArtifacts, dryRun bool
+	var rps float64
+	var reportFormat, reportFile string
+	var metricsAddr, otelEndpoint string
+	var authMethod, caCertPath string
+	var insecureSkipVerify bool
+	var allExpired bool
+	var projectsFlag, projectsFile string
+	var projectConcurrency int
 
 	rootCmd := &cobra.Command{
 		Use:   "artifact-cleaner",
 		Short: "Delete GitLab job artifacts concurrently",
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := validateInputs(server, token, projectID, startJob, endJob, concurrency, projectConcurrency); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
 			// Open log file
 			f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 			if err != nil {
@@ -120,32 +497,80 @@ func main() {
 			defer f.Close()
 			logger := log.New(f, "", log.LstdFlags)
 
-			// Validate project exists
-			exists, err := projectExists(server, token, projectID)
+			ctx := context.Background()
+			m, shutdownObservability, err := setupObservability(ctx, metricsAddr, otelEndpoint, logger)
 			if err != nil {
-				logger.Printf("Error checking project: %v\n", err)
-				fmt.Printf("Error checking project: %v\n", err)
-				return
+				fmt.Println(err)
+				os.Exit(1)
 			}
-			if !exists {
-				msg := fmt.Sprintf("Project %d does not exist on %s", projectID, server)
-				logger.Println(msg)
-				fmt.Println(msg)
-				return
+			defer shutdownObservability()
+
+			client, err := buildClient(server, token, authMethod, caCertPath, insecureSkipVerify)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
 			}
 
-			// Delete artifacts concurrently
-			var wg sync.WaitGroup
-			sem := make(chan struct{}, concurrency)
-			var successCounter, failureCounter int
-			var mu sync.Mutex
+			rpt, closeReport, err := openReportWriter(reportFile, reportFormat)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer closeReport()
 
-			for jobID := startJob; jobID <= endJob; jobID++ {
-				wg.Add(1)
-				go deleteArtifact(server, token, projectID, jobID, &wg, sem, &successCounter, &failureCounter, &mu, logger)
+			projects, err := resolveProjects(projectID, projectsFlag, projectsFile)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			opts := cleanOptions{
+				server:            server,
+				startJob:          startJob,
+				endJob:            endJob,
+				concurrency:       concurrency,
+				pipelineID:        pipelineID,
+				scopes:            scopes,
+				ref:               ref,
+				olderThan:         olderThan,
+				onlyWithArtifacts: onlyWithArtifacts,
+				allExpired:        allExpired,
+				dryRun:            dryRun,
+				rps:               rps,
+				burst:             burst,
+				// One limiter shared by every project's scheduler: --rps caps
+				// requests against the host in aggregate, not per project.
+				limiter: scheduler.NewLimiter(rps, burstOrDefault(burst, concurrency)),
 			}
 
+			// Bound how many projects are cleaned concurrently; each project's
+			// own job-level parallelism is still bounded by --gitlab-concurrency.
+			sem := make(chan struct{}, projectConcurrency)
+			results := make([]projectResult, len(projects))
+			var wg sync.WaitGroup
+			for i, project := range projects {
+				wg.Add(1)
+				go func(i int, project string) {
+					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					results[i] = cleanProject(ctx, client, m, rpt, logger, project, opts)
+				}(i, project)
+			}
 			wg.Wait()
+
+			if len(projects) > 1 {
+				printProjectSummary(results)
+			}
+			var successCounter, failureCounter int
+			for _, r := range results {
+				successCounter += r.successes
+				failureCounter += r.failures
+				if r.err != nil {
+					logger.Printf("Project %s: %v\n", r.project, r.err)
+					fmt.Printf("Project %s: %v\n", r.project, r.err)
+				}
+			}
 			summary := fmt.Sprintf("All artifact deletions attempted. Successes: %d, Failures: %d", successCounter, failureCounter)
 			fmt.Println(summary)
 			logger.Println(summary)
@@ -158,23 +583,57 @@ func main() {
 	endJobDefault, _ := strconv.Atoi(getEnv("GITLAB_END_JOB", "120"))
 	concurrencyDefault, _ := strconv.Atoi(getEnv("GITLAB_CONCURRENCY", "100"))
 
-	// Flags with environment variable defaults
-	rootCmd.Flags().StringVar(&server, "gitlab-server", getEnv("GITLAB_SERVER", "gitlab.example.com"), "GitLab server URL")
-	rootCmd.Flags().StringVar(&token, "gitlab-token", getEnv("GITLAB_TOKEN", ""), "GitLab private token")
-	rootCmd.Flags().IntVar(&projectID, "project", projectID, "GitLab project ID")
-	rootCmd.Flags().IntVar(&startJob, "gitlab-start-job", startJobDefault, "Starting job ID")
-	rootCmd.Flags().IntVar(&endJob, "gitlab-end-job", endJobDefault, "Ending job ID")
+	// Flags shared with subcommands (e.g. "prune") with environment variable defaults
+	rootCmd.PersistentFlags().StringVar(&server, "gitlab-server", getEnv("GITLAB_SERVER", "gitlab.example.com"), "GitLab server URL")
+	rootCmd.PersistentFlags().StringVar(&token, "gitlab-token", getEnv("GITLAB_TOKEN", ""), "GitLab private token")
+	rootCmd.PersistentFlags().IntVar(&projectID, "project", projectID, "GitLab project ID")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "artifact-cleaner.log", "Path to log file")
+	rootCmd.PersistentFlags().Float64Var(&rps, "rps", 0, "Maximum GitLab API requests per second (0 = unlimited)")
+	rootCmd.PersistentFlags().IntVar(&burst, "burst", 0, "Burst size for --rps (defaults to --gitlab-concurrency)")
+
+	// Auth and TLS flags.
+	rootCmd.PersistentFlags().StringVar(&authMethod, "auth-method", string(gitlab.AuthMethodToken), "How --gitlab-token is presented: token, oauth or job-token")
+	rootCmd.PersistentFlags().StringVar(&caCertPath, "ca-cert", "", "Path to a PEM-encoded CA certificate to trust, for self-hosted GitLab instances")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Skip TLS certificate verification (insecure, for testing only)")
+
+	// Flags specific to the default (range/discovery based) deletion mode
+	rootCmd.Flags().IntVar(&startJob, "gitlab-start-job", startJobDefault, "Starting job ID (used when no discovery flags are set)")
+	rootCmd.Flags().IntVar(&endJob, "gitlab-end-job", endJobDefault, "Ending job ID (used when no discovery flags are set)")
 	rootCmd.Flags().IntVar(&concurrency, "gitlab-concurrency", concurrencyDefault, "Maximum concurrent deletions")
-	rootCmd.Flags().StringVar(&logFile, "log-file", "artifact-cleaner.log", "Path to log file")
+
+	// Discovery flags: when any of these are set, jobs are enumerated via
+	// the Jobs API instead of walking the start/end range.
+	rootCmd.Flags().StringSliceVar(&scopes, "scope", nil, "Only consider jobs in these scopes (success, failed, canceled, skipped, ...)")
+	rootCmd.Flags().IntVar(&pipelineID, "pipeline", 0, "Only consider jobs belonging to this pipeline ID")
+	rootCmd.Flags().StringVar(&ref, "ref", "", "Only consider jobs built from this ref")
+	rootCmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only consider jobs whose finished_at is older than this duration")
+	rootCmd.Flags().BoolVar(&onlyWithArtifacts, "only-with-artifacts", false, "Skip jobs that have no artifacts")
+
+	// Bulk expiration and multi-project flags.
+	rootCmd.Flags().BoolVar(&allExpired, "all-expired", false, "Expire all eligible artifacts in the project with one call, instead of deleting job by job")
+	rootCmd.PersistentFlags().StringVar(&projectsFlag, "projects", "", "Comma-separated project IDs or paths to clean (overrides --project)")
+	rootCmd.PersistentFlags().StringVar(&projectsFile, "projects-file", "", "Path to a file listing one project ID or path per line (overrides --project)")
+	rootCmd.PersistentFlags().IntVar(&projectConcurrency, "project-concurrency", 4, "Maximum number of projects to clean concurrently")
+
+	// Dry-run and reporting flags, shared with "prune".
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Validate the project and discover jobs, but never delete artifacts")
+	rootCmd.PersistentFlags().StringVar(&reportFormat, "report-format", "text", "Report output format: json, ndjson or text")
+	rootCmd.PersistentFlags().StringVar(&reportFile, "report-file", "", "Write the report to this path instead of stdout")
+
+	// Observability flags.
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090); disabled if empty")
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", "OTLP/HTTP endpoint to export traces to; disabled if empty")
 
 	// Only mark required if no env var is set
 	if getEnv("GITLAB_TOKEN", "") == "" {
-		rootCmd.MarkFlagRequired("gitlab-token")
+		rootCmd.MarkPersistentFlagRequired("gitlab-token")
 	}
 	if getEnv("GITLAB_PROJECT_ID", "") == "" {
-		rootCmd.MarkFlagRequired("project")
+		rootCmd.MarkPersistentFlagRequired("project")
 	}
 
+	rootCmd.AddCommand(newPruneCmd(&server, &token, &projectID, &logFile, &rps, &burst, &dryRun, &reportFormat, &reportFile, &metricsAddr, &otelEndpoint, &authMethod, &caCertPath, &insecureSkipVerify))
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
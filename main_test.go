@@ -42,110 +42,153 @@ func TestGetEnv(t *testing.T) {
 
 func TestValidateInputs(t *testing.T) {
 	tests := []struct {
-		name        string
-		server      string
-		token       string
-		projectID   int
-		startJob    int
-		endJob      int
-		concurrency int
-		wantErr     bool
+		name               string
+		server             string
+		token              string
+		projectID          int
+		startJob           int
+		endJob             int
+		concurrency        int
+		projectConcurrency int
+		wantErr            bool
 	}{
 		{
-			name:        "valid inputs",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    1,
-			endJob:      10,
-			concurrency: 5,
-			wantErr:     false,
+			name:               "valid inputs",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            false,
 		},
 		{
-			name:        "empty server",
-			server:      "",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    1,
-			endJob:      10,
-			concurrency: 5,
-			wantErr:     true,
+			name:               "empty server",
+			server:             "",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "empty token",
-			server:      "gitlab.example.com",
-			token:       "",
-			projectID:   1,
-			startJob:    1,
-			endJob:      10,
-			concurrency: 5,
-			wantErr:     true,
+			name:               "empty token",
+			server:             "gitlab.example.com",
+			token:              "",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "invalid project ID",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   0,
-			startJob:    1,
-			endJob:      10,
-			concurrency: 5,
-			wantErr:     true,
+			name:               "invalid project ID",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          0,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "invalid start job",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    0,
-			endJob:      10,
-			concurrency: 5,
-			wantErr:     true,
+			name:               "invalid start job",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           0,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "end job less than start job",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    10,
-			endJob:      5,
-			concurrency: 5,
-			wantErr:     true,
+			name:               "end job less than start job",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           10,
+			endJob:             5,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "concurrency too low",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    1,
-			endJob:      10,
-			concurrency: 0,
-			wantErr:     true,
+			name:               "concurrency too low",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        0,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "concurrency too high",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    1,
-			endJob:      10,
-			concurrency: 1001,
-			wantErr:     true,
+			name:               "concurrency too high",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        1001,
+			projectConcurrency: 4,
+			wantErr:            true,
 		},
 		{
-			name:        "job range too large",
-			server:      "gitlab.example.com",
-			token:       "valid-token",
-			projectID:   1,
-			startJob:    1,
-			endJob:      1000001,
-			concurrency: 5,
-			wantErr:     true,
+			name:               "job range too large",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             1000001,
+			concurrency:        5,
+			projectConcurrency: 4,
+			wantErr:            true,
+		},
+		{
+			name:               "project concurrency zero",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 0,
+			wantErr:            true,
+		},
+		{
+			name:               "project concurrency negative",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: -1,
+			wantErr:            true,
+		},
+		{
+			name:               "project concurrency too high",
+			server:             "gitlab.example.com",
+			token:              "valid-token",
+			projectID:          1,
+			startJob:           1,
+			endJob:             10,
+			concurrency:        5,
+			projectConcurrency: 1001,
+			wantErr:            true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateInputs(tt.server, tt.token, tt.projectID, tt.startJob, tt.endJob, tt.concurrency)
+			err := validateInputs(tt.server, tt.token, tt.projectID, tt.startJob, tt.endJob, tt.concurrency, tt.projectConcurrency)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateInputs() error = %v, wantErr %v", err, tt.wantErr)
 			}
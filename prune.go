@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/gitlab"
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/scheduler"
+)
+
+// jobGroupKey groups jobs the way pruning reasons about retention: per ref,
+// per job name (e.g. "test", "build"), independent of pipeline.
+type jobGroupKey struct {
+	ref  string
+	name string
+}
+
+// selectJobsToPrune groups jobs by (ref, name), keeps the newest keepLatest
+// per group (ordered by CreatedAt, newest first) and returns the rest. Tag
+// pipeline jobs are skipped entirely when keepTagged is true, and
+// non-successful jobs are skipped when successOnly is true.
+func selectJobsToPrune(jobs []gitlab.Job, keepLatest int, keepTagged, successOnly bool) []gitlab.Job {
+	groups := make(map[jobGroupKey][]gitlab.Job)
+	for _, job := range jobs {
+		if keepTagged && job.Tag {
+			continue
+		}
+		if successOnly && job.Status != "success" {
+			continue
+		}
+		key := jobGroupKey{ref: job.Ref, name: job.Name}
+		groups[key] = append(groups[key], job)
+	}
+
+	var toPrune []gitlab.Job
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].CreatedAt.After(group[j].CreatedAt)
+		})
+		if len(group) <= keepLatest {
+			continue
+		}
+		toPrune = append(toPrune, group[keepLatest:]...)
+	}
+
+	sort.Slice(toPrune, func(i, j int) bool { return toPrune[i].ID < toPrune[j].ID })
+	return toPrune
+}
+
+// newPruneCmd builds the "prune" subcommand, which applies a keep-latest-N
+// retention policy across all jobs in a project instead of deleting an
+// explicit range or discovery result.
+func newPruneCmd(server, token *string, projectID *int, logFile *string, rps *float64, burst *int, dryRun *bool, reportFormat, reportFile *string, metricsAddr, otelEndpoint *string, authMethod, caCertPath *string, insecureSkipVerify *bool) *cobra.Command {
+	var keepLatest, concurrency int
+	var keepTagged, keepSuccessOnly bool
+	var pipelineID int
+	var ref string
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete artifacts for all but the newest N jobs per ref/job-name",
+		Run: func(cmd *cobra.Command, args []string) {
+			if keepLatest < 0 {
+				fmt.Println("--keep-latest must not be negative")
+				os.Exit(1)
+			}
+
+			f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				fmt.Printf("Failed to open log file %s: %v\n", *logFile, err)
+				return
+			}
+			defer f.Close()
+			logger := log.New(f, "", log.LstdFlags)
+
+			ctx := context.Background()
+			m, shutdownObservability, err := setupObservability(ctx, *metricsAddr, *otelEndpoint, logger)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer shutdownObservability()
+
+			client, err := buildClient(*server, *token, *authMethod, *caCertPath, *insecureSkipVerify)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			exists, err := projectExists(ctx, m, client, *projectID)
+			if err != nil {
+				fmt.Printf("Error checking project: %v\n", err)
+				logger.Printf("Error checking project: %v\n", err)
+				return
+			}
+			if !exists {
+				msg := fmt.Sprintf("Project %d does not exist on %s", *projectID, *server)
+				fmt.Println(msg)
+				logger.Println(msg)
+				return
+			}
+
+			rpt, closeReport, err := openReportWriter(*reportFile, *reportFormat)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer closeReport()
+
+			sched := scheduler.New(concurrency, scheduler.NewLimiter(*rps, burstOrDefault(*burst, concurrency)))
+			defer sched.Close()
+
+			onResponse := func(statusCode int) { m.ObserveAPIRequest(strconv.Itoa(statusCode)) }
+
+			var jobs []gitlab.Job
+			if pipelineID > 0 {
+				jobs, err = client.ListPipelineJobs(ctx, *projectID, pipelineID, gitlab.ListJobsOptions{Scheduler: sched, OnResponse: onResponse})
+			} else {
+				jobs, err = client.ListProjectJobs(ctx, *projectID, gitlab.ListJobsOptions{Scheduler: sched, OnResponse: onResponse})
+			}
+			if err != nil {
+				fmt.Printf("Error listing jobs: %v\n", err)
+				logger.Printf("Error listing jobs: %v\n", err)
+				return
+			}
+
+			if ref != "" {
+				filtered := jobs[:0]
+				for _, job := range jobs {
+					if job.Ref == ref {
+						filtered = append(filtered, job)
+					}
+				}
+				jobs = filtered
+			}
+
+			toPrune := selectJobsToPrune(jobs, keepLatest, keepTagged, keepSuccessOnly)
+			if len(toPrune) == 0 {
+				fmt.Println("Nothing to prune: every group is within the retention limit")
+				logger.Println("Nothing to prune: every group is within the retention limit")
+				return
+			}
+
+			var wg sync.WaitGroup
+			var successCounter, failureCounter int
+			var mu sync.Mutex
+
+			for _, job := range toPrune {
+				wg.Add(1)
+				go func(job gitlab.Job) {
+					defer wg.Done()
+					deleteArtifact(ctx, client, sched, m, rpt, *dryRun, *projectID, job, &successCounter, &failureCounter, &mu, logger)
+				}(job)
+			}
+			wg.Wait()
+
+			summary := fmt.Sprintf("Pruned %d job(s). Successes: %d, Failures: %d", len(toPrune), successCounter, failureCounter)
+			fmt.Println(summary)
+			logger.Println(summary)
+		},
+	}
+
+	cmd.Flags().IntVar(&keepLatest, "keep-latest", 3, "Number of newest jobs to retain per (ref, job name) group")
+	cmd.Flags().BoolVar(&keepTagged, "keep-tagged", false, "Always preserve jobs built from tag pipelines")
+	cmd.Flags().BoolVar(&keepSuccessOnly, "keep-success-only", false, "Only prune successful jobs, leaving failed builds for investigation")
+	cmd.Flags().IntVar(&pipelineID, "pipeline", 0, "Only consider jobs belonging to this pipeline ID")
+	cmd.Flags().StringVar(&ref, "ref", "", "Only consider jobs built from this ref")
+	cmd.Flags().IntVar(&concurrency, "gitlab-concurrency", 100, "Maximum concurrent deletions")
+
+	return cmd
+}
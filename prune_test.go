@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dxas90/gitlab-artifacts-cleaner/internal/gitlab"
+)
+
+func TestSelectJobsToPrune(t *testing.T) {
+	now := time.Now()
+	job := func(id int, ref, name, status string, tag bool, age time.Duration) gitlab.Job {
+		return gitlab.Job{
+			ID:        id,
+			Ref:       ref,
+			Name:      name,
+			Status:    status,
+			Tag:       tag,
+			CreatedAt: now.Add(-age),
+		}
+	}
+
+	t.Run("keeps newest N per ref/name group", func(t *testing.T) {
+		jobs := []gitlab.Job{
+			job(1, "main", "build", "success", false, 4*time.Hour),
+			job(2, "main", "build", "success", false, 3*time.Hour),
+			job(3, "main", "build", "success", false, 2*time.Hour),
+			job(4, "main", "build", "success", false, 1*time.Hour),
+		}
+		got := jobIDs(selectJobsToPrune(jobs, 2, false, false))
+		want := []int{1, 2}
+		if !intSlicesEqual(got, want) {
+			t.Errorf("selectJobsToPrune() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("separate groups per ref and per name", func(t *testing.T) {
+		jobs := []gitlab.Job{
+			job(1, "main", "build", "success", false, 2*time.Hour),
+			job(2, "main", "test", "success", false, 2*time.Hour),
+			job(3, "dev", "build", "success", false, 2*time.Hour),
+		}
+		got := selectJobsToPrune(jobs, 1, false, false)
+		if len(got) != 0 {
+			t.Errorf("selectJobsToPrune() = %v, want empty (each group has exactly 1 job)", got)
+		}
+	})
+
+	t.Run("keep-tagged preserves tag pipeline jobs", func(t *testing.T) {
+		jobs := []gitlab.Job{
+			job(1, "v1.0.0", "build", "success", true, 4*time.Hour),
+			job(2, "main", "build", "success", false, 3*time.Hour),
+			job(3, "main", "build", "success", false, 2*time.Hour),
+			job(4, "main", "build", "success", false, 1*time.Hour),
+		}
+		got := jobIDs(selectJobsToPrune(jobs, 2, true, false))
+		want := []int{2}
+		if !intSlicesEqual(got, want) {
+			t.Errorf("selectJobsToPrune() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("keep-success-only leaves failed jobs alone", func(t *testing.T) {
+		jobs := []gitlab.Job{
+			job(1, "main", "build", "failed", false, 4*time.Hour),
+			job(2, "main", "build", "success", false, 3*time.Hour),
+			job(3, "main", "build", "success", false, 2*time.Hour),
+			job(4, "main", "build", "success", false, 1*time.Hour),
+		}
+		got := jobIDs(selectJobsToPrune(jobs, 2, false, true))
+		want := []int{2}
+		if !intSlicesEqual(got, want) {
+			t.Errorf("selectJobsToPrune() = %v, want %v", got, want)
+		}
+	})
+}
+
+func jobIDs(jobs []gitlab.Job) []int {
+	ids := make([]int, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return ids
+}
+
+func intSlicesEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}